@@ -0,0 +1,251 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+// defaultConnectionName is the profile name used by the config/connection
+// alias (as opposed to a named config/connection/:name profile) and by
+// roles that don't set an explicit "connection" field.
+const defaultConnectionName = "default"
+
+func Factory(conf *logical.BackendConfig) (logical.Backend, error) {
+	b := Backend()
+	_, err := b.Setup(conf)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func Backend() *backend {
+	var b backend
+	b.profiles = make(map[string]*connProfile)
+	b.Backend = &framework.Backend{
+		Help: strings.TrimSpace(backendHelp),
+
+		Paths: []*framework.Path{
+			pathConfigConnection(&b),
+			pathConfigConnectionDefault(&b),
+			pathListRoles(&b),
+			pathRoles(&b),
+			pathRoleCreate(&b),
+		},
+
+		Secrets: []*framework.Secret{
+			secretCreds(&b),
+		},
+	}
+
+	return &b
+}
+
+type backend struct {
+	*framework.Backend
+
+	// profiles caches connection state per named profile (see
+	// config/connection/:name), keyed by profile name. profilesMu guards
+	// only the map itself; dialing/pinging a profile's connections is
+	// guarded by that profile's own connProfile.mu, so unrelated profiles
+	// never block on each other's network I/O.
+	profilesMu sync.Mutex
+	profiles   map[string]*connProfile
+}
+
+// connProfile holds the cached write and reader connections for a single
+// named profile.
+type connProfile struct {
+	mu         sync.Mutex
+	conn       Conn
+	readerConn Conn
+}
+
+// profile returns the connProfile for name, creating it if it doesn't
+// already exist.
+func (b *backend) profile(name string) *connProfile {
+	b.profilesMu.Lock()
+	defer b.profilesMu.Unlock()
+
+	p, ok := b.profiles[name]
+	if !ok {
+		p = &connProfile{}
+		b.profiles[name] = p
+	}
+	return p
+}
+
+// connectionStorageKey returns the storage entry key for a named profile.
+func connectionStorageKey(name string) string {
+	if name == "" {
+		name = defaultConnectionName
+	}
+	return "config/connection/" + name
+}
+
+// legacyConnectionStorageKey is where the (sole) connection profile was
+// stored before config/connection/:name was introduced.
+const legacyConnectionStorageKey = "config/connection"
+
+// loadConnectionConfig fetches the stored connectionConfig for a named
+// profile. If the "default" profile hasn't been written to its
+// config/connection/:name key yet, it falls back to the legacy
+// config/connection key used before named connections were supported, and
+// migrates that entry forward so future lookups hit the new key directly.
+func loadConnectionConfig(s logical.Storage, name string) (*connectionConfig, error) {
+	entry, err := s.Get(connectionStorageKey(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil && name == defaultConnectionName {
+		legacyEntry, err := s.Get(legacyConnectionStorageKey)
+		if err != nil {
+			return nil, err
+		}
+		if legacyEntry != nil {
+			migrated := &logical.StorageEntry{Key: connectionStorageKey(name), Value: legacyEntry.Value}
+			if err := s.Put(migrated); err != nil {
+				return nil, err
+			}
+			entry = migrated
+		}
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	var connConfig connectionConfig
+	if err := entry.DecodeJSON(&connConfig); err != nil {
+		return nil, err
+	}
+	return &connConfig, nil
+}
+
+// WriteDB returns the primary database connection for the named profile,
+// used for writes such as CREATE ROLE and REVOKE. It's created from the
+// profile's config/connection/:name entry if it doesn't already exist or
+// has gone stale.
+func (b *backend) WriteDB(s logical.Storage, name string) (Conn, error) {
+	if name == "" {
+		name = defaultConnectionName
+	}
+
+	p := b.profile(name)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return b.writeConnLocked(p, s, name)
+}
+
+// writeConnLocked does the actual work for WriteDB, assuming p.mu is
+// already held by the caller.
+func (b *backend) writeConnLocked(p *connProfile, s logical.Storage, name string) (Conn, error) {
+	ctx := context.Background()
+
+	if p.conn != nil {
+		if err := p.conn.Ping(ctx); err == nil {
+			return p.conn, nil
+		}
+		p.conn.Close()
+		p.conn = nil
+	}
+
+	connConfig, err := loadConnectionConfig(s, name)
+	if err != nil {
+		return nil, err
+	}
+	if connConfig == nil {
+		return nil, fmt.Errorf("configure the DB connection with config/connection/%s first", name)
+	}
+
+	conn, err := newConn(ctx, *connConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.conn = conn
+	return conn, nil
+}
+
+// ReadDB returns the connection used for read-only operations against the
+// named profile, such as validating that a role still exists on lease
+// renewal. If no reader connection is configured for that profile, it
+// falls back to the primary (write) connection.
+func (b *backend) ReadDB(s logical.Storage, name string) (Conn, error) {
+	if name == "" {
+		name = defaultConnectionName
+	}
+
+	p := b.profile(name)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ctx := context.Background()
+
+	connConfig, err := loadConnectionConfig(s, name)
+	if err != nil {
+		return nil, err
+	}
+	if connConfig == nil {
+		return nil, fmt.Errorf("configure the DB connection with config/connection/%s first", name)
+	}
+
+	if connConfig.ReaderConnectionURL == "" {
+		return b.writeConnLocked(p, s, name)
+	}
+
+	if p.readerConn != nil {
+		if err := p.readerConn.Ping(ctx); err == nil {
+			return p.readerConn, nil
+		}
+		p.readerConn.Close()
+		p.readerConn = nil
+	}
+
+	conn, err := newConn(ctx, connConfig.readerConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	p.readerConn = conn
+	return conn, nil
+}
+
+// ResetDB forces new connections to be established for the named profile
+// next time WriteDB() or ReadDB() is called. Other profiles are left
+// untouched so reconfiguring one database/cluster doesn't disrupt
+// in-flight work against an unrelated one.
+func (b *backend) ResetDB(name string) {
+	if name == "" {
+		name = defaultConnectionName
+	}
+
+	p := b.profile(name)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn = nil
+	}
+	if p.readerConn != nil {
+		p.readerConn.Close()
+		p.readerConn = nil
+	}
+}
+
+const backendHelp = `
+The PostgreSQL backend dynamically generates database users.
+
+After mounting this backend, configure it using the endpoints within
+the "config/" path. Multiple named connections can be configured under
+config/connection/:name, each brokering credentials to a different
+database; config/connection is an alias for the "default" profile.
+`
@@ -0,0 +1,85 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/logical"
+)
+
+// memStorage is a minimal in-memory logical.Storage for testing.
+type memStorage struct {
+	entries map[string]*logical.StorageEntry
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{entries: make(map[string]*logical.StorageEntry)}
+}
+
+func (m *memStorage) Get(key string) (*logical.StorageEntry, error) {
+	return m.entries[key], nil
+}
+
+func (m *memStorage) Put(entry *logical.StorageEntry) error {
+	m.entries[entry.Key] = entry
+	return nil
+}
+
+func (m *memStorage) Delete(key string) error {
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *memStorage) List(prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func TestLoadConnectionConfigFallsBackToLegacyKey(t *testing.T) {
+	s := newMemStorage()
+
+	legacy := connectionConfig{ConnectionString: "user=foo"}
+	entry, err := logical.StorageEntryJSON(legacyConnectionStorageKey, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConnectionConfig(s, defaultConnectionName)
+	if err != nil {
+		t.Fatalf("loadConnectionConfig: unexpected error: %s", err)
+	}
+	if config == nil {
+		t.Fatal("loadConnectionConfig: expected config from legacy key, got nil")
+	}
+	if config.ConnectionString != "user=foo" {
+		t.Errorf("loadConnectionConfig: ConnectionString = %q, want %q", config.ConnectionString, "user=foo")
+	}
+
+	// The legacy entry should have been migrated to the new key so future
+	// lookups don't need the fallback.
+	if _, ok := s.entries[connectionStorageKey(defaultConnectionName)]; !ok {
+		t.Error("loadConnectionConfig: expected legacy entry to be migrated to config/connection/default")
+	}
+}
+
+func TestLoadConnectionConfigNoLegacyFallbackForNamedProfile(t *testing.T) {
+	s := newMemStorage()
+
+	legacy := connectionConfig{ConnectionString: "user=foo"}
+	entry, err := logical.StorageEntryJSON(legacyConnectionStorageKey, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConnectionConfig(s, "reporting")
+	if err != nil {
+		t.Fatalf("loadConnectionConfig: unexpected error: %s", err)
+	}
+	if config != nil {
+		t.Errorf("loadConnectionConfig: expected nil for unconfigured named profile, got %+v", config)
+	}
+}
@@ -0,0 +1,228 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Rows abstracts over *sql.Rows and pgx.Rows so secret_creds.go can
+// iterate query results without caring which driver produced them.
+type Rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Close() error
+	Err() error
+}
+
+// Result abstracts over sql.Result and pgx's command tag.
+type Result interface {
+	RowsAffected() (int64, error)
+}
+
+// Tx is the subset of transaction behavior the backend needs to issue the
+// CREATE ROLE / REVOKE / DROP ROLE statements used to create and tear down
+// dynamic credentials.
+type Tx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (Result, error)
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// Conn is the interface backend.WriteDB() and backend.ReadDB() return.
+// It's implemented by both the
+// lib/pq (database/sql) driver and the pgx/pgxpool driver so
+// path_role_create.go and secret_creds.go don't need to care which is in
+// use.
+type Conn interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (Result, error)
+	BeginTx(ctx context.Context) (Tx, error)
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// newConn builds the Conn for the configured driver ("pq" or "pgx"),
+// opening (and for pgx, health-checking) the underlying pool.
+func newConn(ctx context.Context, cfg connectionConfig) (Conn, error) {
+	switch cfg.Driver {
+	case "", "pq":
+		return newPqConn(cfg)
+	case "pgx":
+		return newPgxConn(ctx, cfg)
+	default:
+		return nil, &connConfigError{"driver", errUnknownDriver(cfg.Driver)}
+	}
+}
+
+type errUnknownDriver string
+
+func (e errUnknownDriver) Error() string {
+	return `unknown driver "` + string(e) + `", expected "pq" or "pgx"`
+}
+
+// pqConn wraps a *sql.DB opened with the lib/pq driver.
+type pqConn struct {
+	db *sql.DB
+}
+
+func newPqConn(cfg connectionConfig) (Conn, error) {
+	connString := cfg.effectiveConnString()
+
+	db, err := sql.Open("postgres", connString)
+	if err != nil {
+		return nil, &connConfigError{"sql.Open", err}
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConnections)
+	db.SetMaxIdleConns(cfg.MaxIdleConnections)
+	db.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnectionMaxIdleTime)
+
+	return &pqConn{db: db}, nil
+}
+
+func (c *pqConn) QueryContext(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (c *pqConn) ExecContext(ctx context.Context, query string, args ...interface{}) (Result, error) {
+	return c.db.ExecContext(ctx, query, args...)
+}
+
+func (c *pqConn) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &pqTx{tx: tx}, nil
+}
+
+func (c *pqConn) Ping(ctx context.Context) error { return c.db.PingContext(ctx) }
+func (c *pqConn) Close() error                   { return c.db.Close() }
+
+type pqTx struct {
+	tx *sql.Tx
+}
+
+func (t *pqTx) ExecContext(ctx context.Context, query string, args ...interface{}) (Result, error) {
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+func (t *pqTx) Commit(ctx context.Context) error   { return t.tx.Commit() }
+func (t *pqTx) Rollback(ctx context.Context) error { return t.tx.Rollback() }
+
+// pgxConn wraps a *pgxpool.Pool.
+type pgxConn struct {
+	pool *pgxpool.Pool
+}
+
+func newPgxConn(ctx context.Context, cfg connectionConfig) (Conn, error) {
+	connString := cfg.effectiveConnString()
+
+	poolCfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, &connConfigError{"pgx config parsing", err}
+	}
+
+	switch {
+	case cfg.MaxOpenConnections > 0:
+		poolCfg.MaxConns = int32(cfg.MaxOpenConnections)
+	case cfg.MaxOpenConnections < 0:
+		// pgxpool has no "unbounded" sentinel of its own, so approximate
+		// max_open_connections' documented "negative means unlimited" with
+		// the largest pool size it accepts.
+		poolCfg.MaxConns = math.MaxInt32
+	}
+	if cfg.PgxMinConns > 0 {
+		poolCfg.MinConns = int32(cfg.PgxMinConns)
+	}
+	if cfg.ConnectionMaxLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.ConnectionMaxLifetime
+	}
+	if cfg.ConnectionMaxIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.ConnectionMaxIdleTime
+	}
+	if cfg.PgxHealthCheckPeriod > 0 {
+		poolCfg.HealthCheckPeriod = cfg.PgxHealthCheckPeriod
+	}
+	if cfg.PgxStatementCacheCapacity > 0 {
+		poolCfg.ConnConfig.StatementCacheCapacity = cfg.PgxStatementCacheCapacity
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, &connConfigError{"pgx pool", err}
+	}
+
+	return &pgxConn{pool: pool}, nil
+}
+
+func (c *pgxConn) QueryContext(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	rows, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgxRows{rows}, nil
+}
+
+// pgxRows adapts pgx.Rows to the Rows interface: pgx.Rows.Close() has no
+// return value, while database/sql's (and so Rows') Close() returns error.
+type pgxRows struct {
+	rows pgx.Rows
+}
+
+func (r pgxRows) Next() bool                     { return r.rows.Next() }
+func (r pgxRows) Scan(dest ...interface{}) error { return r.rows.Scan(dest...) }
+func (r pgxRows) Err() error                     { return r.rows.Err() }
+
+func (r pgxRows) Close() error {
+	r.rows.Close()
+	return nil
+}
+
+func (c *pgxConn) ExecContext(ctx context.Context, query string, args ...interface{}) (Result, error) {
+	tag, err := c.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgxResult{tag}, nil
+}
+
+func (c *pgxConn) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgxTx{tx: tx}, nil
+}
+
+func (c *pgxConn) Ping(ctx context.Context) error { return c.pool.Ping(ctx) }
+func (c *pgxConn) Close() error                   { c.pool.Close(); return nil }
+
+type pgxResult struct {
+	tag interface{ RowsAffected() int64 }
+}
+
+func (r pgxResult) RowsAffected() (int64, error) { return r.tag.RowsAffected(), nil }
+
+type pgxTx struct {
+	tx pgx.Tx
+}
+
+func (t *pgxTx) ExecContext(ctx context.Context, query string, args ...interface{}) (Result, error) {
+	tag, err := t.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgxResult{tag}, nil
+}
+
+func (t *pgxTx) Commit(ctx context.Context) error   { return t.tx.Commit(ctx) }
+func (t *pgxTx) Rollback(ctx context.Context) error { return t.tx.Rollback(ctx) }
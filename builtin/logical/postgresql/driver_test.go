@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// These var declarations double as compile-time checks that each concrete
+// type actually satisfies the interface it's meant to implement; a
+// mismatched method signature (e.g. Close() vs Close() error) fails the
+// build here instead of at some call site deep in path_role_create.go.
+var (
+	_ Conn = (*pqConn)(nil)
+	_ Conn = (*pgxConn)(nil)
+	_ Tx   = (*pqTx)(nil)
+	_ Tx   = (*pgxTx)(nil)
+	_ Rows = pgxRows{}
+)
+
+func TestNewConnUnknownDriver(t *testing.T) {
+	_, err := newConn(context.Background(), connectionConfig{Driver: "mysql"})
+	if err == nil {
+		t.Fatal("newConn with unknown driver: expected error, got none")
+	}
+}
+
+func TestNewConnDefaultsToPq(t *testing.T) {
+	// database/sql.Open doesn't dial or parse the DSN eagerly, so an empty
+	// Driver should still succeed here and hand back a *pqConn (proving ""
+	// dispatches to newPqConn, not newPgxConn or the unknown-driver branch).
+	conn, err := newConn(context.Background(), connectionConfig{ConnectionString: "user=foo"})
+	if err != nil {
+		t.Fatalf("newConn with empty Driver: unexpected error: %s", err)
+	}
+	defer conn.Close()
+
+	if _, ok := conn.(*pqConn); !ok {
+		t.Fatalf("newConn with empty Driver: got %T, want *pqConn", conn)
+	}
+}
+
+func TestNewConnPgxMalformedConnString(t *testing.T) {
+	// Unlike database/sql, pgxpool.ParseConfig parses the connection string
+	// eagerly, so a malformed one should fail right here.
+	_, err := newConn(context.Background(), connectionConfig{Driver: "pgx", ConnectionString: "not a valid dsn"})
+	if err == nil {
+		t.Fatal("newConn(pgx) with malformed connection string: expected error, got none")
+	}
+	cfgErr, ok := err.(*connConfigError)
+	if !ok {
+		t.Fatalf("newConn(pgx) with malformed connection string: got error of type %T, want *connConfigError", err)
+	}
+	if cfgErr.Stage != "pgx config parsing" {
+		t.Errorf("newConn(pgx) with malformed connection string: got stage %q, want %q", cfgErr.Stage, "pgx config parsing")
+	}
+}
+
+func TestNewPgxConnNegativeMaxOpenConnectionsIsUnlimited(t *testing.T) {
+	// A negative max_open_connections is documented as "unlimited". pgxpool
+	// has no such sentinel of its own, so this should be translated to the
+	// largest pool size it accepts rather than silently falling back to
+	// pgxpool's small built-in default.
+	conn, err := newConn(context.Background(), connectionConfig{
+		Driver:             "pgx",
+		ConnectionString:   "postgres://user@localhost:5432/db",
+		MaxOpenConnections: -1,
+	})
+	if err != nil {
+		t.Fatalf("newConn(pgx) with negative MaxOpenConnections: unexpected error: %s", err)
+	}
+	defer conn.Close()
+
+	pc, ok := conn.(*pgxConn)
+	if !ok {
+		t.Fatalf("newConn(pgx): got %T, want *pgxConn", conn)
+	}
+	if got := pc.pool.Stat().MaxConns(); got != math.MaxInt32 {
+		t.Errorf("pool MaxConns = %d, want %d", got, int32(math.MaxInt32))
+	}
+}
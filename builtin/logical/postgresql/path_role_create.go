@@ -0,0 +1,137 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	uuid "github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+)
+
+func pathRoleCreate(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "creds/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the role.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathRoleCreateRead,
+		},
+
+		HelpSynopsis:    pathRoleCreateReadHelpSyn,
+		HelpDescription: pathRoleCreateReadHelpDesc,
+	}
+}
+
+func (b *backend) pathRoleCreateRead(
+	req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	role, err := b.Role(req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown role: %s", name)), nil
+	}
+
+	connection := role.Connection
+	if connection == "" {
+		connection = defaultConnectionName
+	}
+
+	conn, err := b.WriteDB(req.Storage, connection)
+	if err != nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	username := fmt.Sprintf("%s-%s", req.DisplayName, userUUID)
+	if len(username) > 63 {
+		username = username[:63]
+	}
+
+	password, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := b.System().DefaultLeaseTTL()
+	expiration := time.Now().Add(ttl).Format("2006-01-02 15:04:05-0700")
+
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	for _, query := range strings.Split(role.SQL, ";") {
+		query = strings.TrimSpace(query)
+		if len(query) == 0 {
+			continue
+		}
+
+		query = expandQuery(query, map[string]string{
+			"name":       username,
+			"password":   password,
+			"expiration": expiration,
+		})
+
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	committed = true
+
+	resp := b.Secret(SecretCredsType).Response(map[string]interface{}{
+		"username": username,
+		"password": password,
+	}, map[string]interface{}{
+		"username":   username,
+		"connection": connection,
+	})
+	resp.Secret.TTL = ttl
+	return resp, nil
+}
+
+// expandQuery substitutes "{{key}}"-style placeholders in tpl with the
+// corresponding value from vars.
+func expandQuery(tpl string, vars map[string]string) string {
+	for k, v := range vars {
+		tpl = strings.Replace(tpl, fmt.Sprintf("{{%s}}", k), v, -1)
+	}
+	return tpl
+}
+
+const pathRoleCreateReadHelpSyn = `
+Request database credentials for a role. These credentials are
+created by issuing the role's configured "sql" statement against the
+configured connection.
+`
+
+const pathRoleCreateReadHelpDesc = `
+This path creates database credentials for a particular role. The
+database credentials will be generated on demand and will be automatically
+revoked when the lease is up.
+`
@@ -0,0 +1,128 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+	"github.com/lib/pq"
+)
+
+const SecretCredsType = "creds"
+
+func secretCreds(b *backend) *framework.Secret {
+	return &framework.Secret{
+		Type: SecretCredsType,
+		Fields: map[string]*framework.FieldSchema{
+			"username": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Username",
+			},
+
+			"password": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Password",
+			},
+		},
+
+		Renew:  b.secretCredsRenew,
+		Revoke: b.secretCredsRevoke,
+	}
+}
+
+// secretConnectionName returns the connection profile a secret's
+// credentials were created against, falling back to the "default" profile
+// for secrets leased before named connections were supported.
+func secretConnectionName(internalData map[string]interface{}) string {
+	if raw, ok := internalData["connection"]; ok {
+		if name, ok := raw.(string); ok && name != "" {
+			return name
+		}
+	}
+	return defaultConnectionName
+}
+
+func (b *backend) secretCredsRenew(
+	req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	usernameRaw, ok := req.Secret.InternalData["username"]
+	if !ok {
+		return nil, fmt.Errorf("secret is missing username internal data")
+	}
+	username, ok := usernameRaw.(string)
+	if !ok {
+		return nil, fmt.Errorf("username internal data is not a string")
+	}
+
+	// Validate the role still exists before extending the lease. This is a
+	// read-only query, so it's routed to the reader connection (if
+	// configured) to keep renewal traffic off the primary.
+	conn, err := b.ReadDB(req.Storage, secretConnectionName(req.Secret.InternalData))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	rows, err := conn.QueryContext(ctx, `SELECT 1 FROM pg_roles WHERE rolname = $1`, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	hasRow := rows.Next()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !hasRow {
+		return nil, fmt.Errorf("role %q no longer exists", username)
+	}
+
+	f := framework.LeaseExtend(0, 0, false)
+	return f(req, data)
+}
+
+func (b *backend) secretCredsRevoke(
+	req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	conn, err := b.WriteDB(req.Storage, secretConnectionName(req.Secret.InternalData))
+	if err != nil {
+		return nil, err
+	}
+
+	usernameRaw, ok := req.Secret.InternalData["username"]
+	if !ok {
+		return nil, fmt.Errorf("secret is missing username internal data")
+	}
+	username, ok := usernameRaw.(string)
+	if !ok {
+		return nil, fmt.Errorf("username internal data is not a string")
+	}
+
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	quotedUsername := pq.QuoteIdentifier(username)
+	for _, query := range []string{
+		fmt.Sprintf(`REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA public FROM %s;`, quotedUsername),
+		fmt.Sprintf(`DROP OWNED BY %s;`, quotedUsername),
+		fmt.Sprintf(`DROP ROLE IF EXISTS %s;`, quotedUsername),
+	} {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	committed = true
+
+	return nil, nil
+}